@@ -0,0 +1,16 @@
+package account
+
+// Account is a Base58Check-encoded wallet address, as produced by the
+// wallet package. It replaces free-form usernames as the identity tied
+// to a transaction.
+type Account string
+
+// NewAccount wraps a Base58Check address as an Account.
+func NewAccount(address string) Account {
+	return Account(address)
+}
+
+// String returns the address as a plain string.
+func (a Account) String() string {
+	return string(a)
+}
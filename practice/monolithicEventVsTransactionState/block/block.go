@@ -0,0 +1,103 @@
+package block
+
+import (
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/merkle"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/transaction"
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/json"
+	"time"
+)
+
+// Block groups a batch of transactions and links back to the previous
+// block, forming the chain.
+type Block struct {
+	Timestamp     int64
+	Transactions  []transaction.Tx
+	PrevBlockHash []byte
+	Hash          []byte
+	Nonce         int
+}
+
+// NewBlock mines a new block containing txs on top of prevHash at the
+// given targetBits (see ProofOfWork).
+func NewBlock(txs []transaction.Tx, prevHash []byte, targetBits int) *Block {
+	block := &Block{
+		Timestamp:     time.Now().Unix(),
+		Transactions:  txs,
+		PrevBlockHash: prevHash,
+	}
+
+	pow := NewProofOfWork(block, targetBits)
+	nonce, hash := pow.Run()
+
+	block.Nonce = nonce
+	block.Hash = hash
+
+	return block
+}
+
+// NewGenesisBlock mines the first block of the chain.
+func NewGenesisBlock(txs []transaction.Tx, targetBits int) *Block {
+	return NewBlock(txs, []byte{}, targetBits)
+}
+
+// HashTransactions returns the Merkle root of b's transactions, so the
+// PoW commits to them without requiring a flat concatenation.
+func (b *Block) HashTransactions() []byte {
+	if len(b.Transactions) == 0 {
+		hash := sha256.Sum256(nil)
+		return hash[:]
+	}
+
+	txData := make([][]byte, len(b.Transactions))
+	for i, tx := range b.Transactions {
+		data, err := json.Marshal(tx)
+		if err != nil {
+			panic(err)
+		}
+		txData[i] = data
+	}
+
+	return merkle.NewMerkleTree(txData).RootHash()
+}
+
+// MerkleProof returns a proof that tx is included in b, suitable for a
+// light client to verify with merkle.VerifyMerkleProof against
+// b.HashTransactions().
+func (b *Block) MerkleProof(tx transaction.Tx) ([][]byte, []bool, error) {
+	txData := make([][]byte, len(b.Transactions))
+	for i, t := range b.Transactions {
+		data, err := json.Marshal(t)
+		if err != nil {
+			return nil, nil, err
+		}
+		txData[i] = data
+	}
+
+	txJSON, err := json.Marshal(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return merkle.NewMerkleTree(txData).Proof(txJSON)
+}
+
+// Serialize gob-encodes the block for storage.
+func (b *Block) Serialize() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(b); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Deserialize decodes a block previously produced by Serialize.
+func Deserialize(data []byte) (*Block, error) {
+	var block Block
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&block); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
@@ -0,0 +1,89 @@
+package block
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"math/big"
+)
+
+// DefaultTargetBits is the target-bits value used when a chain's
+// genesis config doesn't set one (or sets a non-positive value).
+const DefaultTargetBits = 16
+
+// maxNonce bounds the search so Run always terminates.
+var maxNonce = math.MaxInt64
+
+// ProofOfWork mines and validates a Block by searching for a Nonce that
+// makes sha256(prevHash‖merkleRoot‖timestamp‖nonce‖targetBits) fall below
+// a difficulty target. targetBits is a chain parameter (genesis.ChainConfig.TargetBits),
+// not a package constant, so different chains can run different difficulties.
+type ProofOfWork struct {
+	block      *Block
+	targetBits int
+	target     *big.Int
+}
+
+// NewProofOfWork builds the PoW puzzle for b at the given targetBits. A
+// non-positive targetBits falls back to DefaultTargetBits.
+func NewProofOfWork(b *Block, targetBits int) *ProofOfWork {
+	if targetBits <= 0 {
+		targetBits = DefaultTargetBits
+	}
+
+	target := big.NewInt(1)
+	target.Lsh(target, uint(256-targetBits))
+	return &ProofOfWork{block: b, targetBits: targetBits, target: target}
+}
+
+func (pow *ProofOfWork) prepareData(nonce int) []byte {
+	return bytes.Join([][]byte{
+		pow.block.PrevBlockHash,
+		pow.block.HashTransactions(),
+		intToBytes(pow.block.Timestamp),
+		intToBytes(int64(nonce)),
+		intToBytes(int64(pow.targetBits)),
+	}, []byte{})
+}
+
+// Run mines the block, returning the winning nonce and its hash.
+func (pow *ProofOfWork) Run() (nonce int, hash []byte) {
+	var hashInt big.Int
+	var hashBytes [32]byte
+
+	for nonce = 0; nonce < maxNonce; nonce++ {
+		hashBytes = sha256.Sum256(pow.prepareData(nonce))
+		hashInt.SetBytes(hashBytes[:])
+
+		if hashInt.Cmp(pow.target) == -1 {
+			break
+		}
+	}
+
+	return nonce, hashBytes[:]
+}
+
+// Validate reports whether the block's stored Nonce satisfies the target.
+func (pow *ProofOfWork) Validate() bool {
+	var hashInt big.Int
+
+	hash := sha256.Sum256(pow.prepareData(pow.block.Nonce))
+	hashInt.SetBytes(hash[:])
+
+	return hashInt.Cmp(pow.target) == -1
+}
+
+// Work is the proof-of-work difficulty credited to a single block at
+// DefaultTargetBits, used to compare chains by cumulative work. It is an
+// approximation: a chain whose genesis configures a different TargetBits
+// will have its actual per-block difficulty under- or over-counted.
+func Work() *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), DefaultTargetBits)
+}
+
+func intToBytes(n int64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(n))
+	return buf
+}
@@ -0,0 +1,124 @@
+package chain
+
+import (
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/account"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/block"
+	"encoding/json"
+	"fmt"
+
+	"go.etcd.io/bbolt"
+)
+
+const (
+	blocksBucket = "blocks"
+	stateBucket  = "state"
+	tipKey       = "l"
+	balancesKey  = "balances"
+)
+
+// BoltStorage is the embedded-key/value-store-backed Storage
+// implementation used in production, persisting to database/chain.db.
+type BoltStorage struct {
+	db *bbolt.DB
+}
+
+// OpenBoltStorage opens (creating if necessary) a BoltDB file at path.
+func OpenBoltStorage(path string) (*BoltStorage, error) {
+	db, err := bbolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not open chain db: %v", err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists([]byte(blocksBucket)); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists([]byte(stateBucket))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize chain db buckets: %v", err)
+	}
+
+	return &BoltStorage{db: db}, nil
+}
+
+func (s *BoltStorage) GetTip() ([]byte, error) {
+	var tip []byte
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		if v := tx.Bucket([]byte(blocksBucket)).Get([]byte(tipKey)); v != nil {
+			tip = append([]byte{}, v...)
+		}
+		return nil
+	})
+	return tip, err
+}
+
+func (s *BoltStorage) GetBlock(hash []byte) (*block.Block, error) {
+	var data []byte
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		data = tx.Bucket([]byte(blocksBucket)).Get(hash)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("block %x not found", hash)
+	}
+
+	return block.Deserialize(data)
+}
+
+// PutBlock stores b and moves the tip pointer to it inside a single Bolt
+// transaction, so a crash can never leave the tip pointing past a block
+// that was never written.
+func (s *BoltStorage) PutBlock(b *block.Block) error {
+	data, err := b.Serialize()
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(blocksBucket))
+		if err := bucket.Put(b.Hash, data); err != nil {
+			return err
+		}
+		return bucket.Put([]byte(tipKey), b.Hash)
+	})
+}
+
+func (s *BoltStorage) SaveBalances(balances map[account.Account]uint) error {
+	data, err := json.Marshal(balances)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket([]byte(stateBucket)).Put([]byte(balancesKey), data)
+	})
+}
+
+func (s *BoltStorage) LoadBalances() (map[account.Account]uint, bool, error) {
+	var data []byte
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		data = tx.Bucket([]byte(stateBucket)).Get([]byte(balancesKey))
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if data == nil {
+		return nil, false, nil
+	}
+
+	balances := make(map[account.Account]uint)
+	if err := json.Unmarshal(data, &balances); err != nil {
+		return nil, false, err
+	}
+	return balances, true, nil
+}
+
+func (s *BoltStorage) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,110 @@
+package chain
+
+import (
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/account"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/block"
+	"fmt"
+)
+
+// Chain is a linked list of blocks backed by a pluggable Storage.
+type Chain struct {
+	Tip []byte
+
+	storage Storage
+}
+
+// LoadChain opens a chain backed by storage, picking up its existing tip
+// (if any).
+func LoadChain(storage Storage) (*Chain, error) {
+	tip, err := storage.GetTip()
+	if err != nil {
+		return nil, fmt.Errorf("could not load chain tip: %v", err)
+	}
+	return &Chain{Tip: tip, storage: storage}, nil
+}
+
+// AddBlock validates b's proof of work against targetBits and atomically
+// appends it to storage, advancing the tip.
+func (c *Chain) AddBlock(b *block.Block, targetBits int) error {
+	if !block.NewProofOfWork(b, targetBits).Validate() {
+		return fmt.Errorf("block %x has an invalid proof of work", b.Hash)
+	}
+
+	if err := c.storage.PutBlock(b); err != nil {
+		return fmt.Errorf("could not persist block: %v", err)
+	}
+
+	c.Tip = b.Hash
+	return nil
+}
+
+// GetBlock looks up a block by its hash.
+func (c *Chain) GetBlock(hash []byte) (*block.Block, error) {
+	return c.storage.GetBlock(hash)
+}
+
+// BlocksInOrder returns every block in the chain, oldest first, by
+// walking the Iterator and reversing it.
+func (c *Chain) BlocksInOrder() ([]*block.Block, error) {
+	var blocks []*block.Block
+	it := c.Iterator()
+	for {
+		b, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			break
+		}
+		blocks = append(blocks, b)
+	}
+
+	for i, j := 0, len(blocks)-1; i < j; i, j = i+1, j-1 {
+		blocks[i], blocks[j] = blocks[j], blocks[i]
+	}
+	return blocks, nil
+}
+
+// SaveBalances persists a balance snapshot so the next boot can skip
+// replaying every block.
+func (c *Chain) SaveBalances(balances map[account.Account]uint) error {
+	return c.storage.SaveBalances(balances)
+}
+
+// LoadBalances returns the last snapshot saved by SaveBalances.
+func (c *Chain) LoadBalances() (map[account.Account]uint, bool, error) {
+	return c.storage.LoadBalances()
+}
+
+// Close releases the underlying storage.
+func (c *Chain) Close() error {
+	return c.storage.Close()
+}
+
+// Iterator returns an iterator that walks the chain backwards from the
+// tip.
+func (c *Chain) Iterator() *Iterator {
+	return &Iterator{storage: c.storage, currentHash: c.Tip}
+}
+
+// Iterator walks a Chain from the tip back to the genesis block.
+type Iterator struct {
+	storage     Storage
+	currentHash []byte
+}
+
+// Next returns the current block and rewinds the iterator to its parent.
+// It returns a nil block once the genesis block has been returned.
+func (it *Iterator) Next() (*block.Block, error) {
+	if len(it.currentHash) == 0 {
+		return nil, nil
+	}
+
+	b, err := it.storage.GetBlock(it.currentHash)
+	if err != nil {
+		return nil, err
+	}
+
+	it.currentHash = b.PrevBlockHash
+	return b, nil
+}
@@ -0,0 +1,30 @@
+package chain
+
+import (
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/account"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/block"
+)
+
+// Storage is the persistence backend a Chain is built on. It lets the
+// chain package stay agnostic of the underlying database engine.
+type Storage interface {
+	// GetTip returns the current tip hash, or nil if the store is empty.
+	GetTip() ([]byte, error)
+
+	// GetBlock looks up a block by its hash.
+	GetBlock(hash []byte) (*block.Block, error)
+
+	// PutBlock stores b and advances the tip to b.Hash, atomically.
+	PutBlock(b *block.Block) error
+
+	// SaveBalances persists a balance snapshot so a future boot does not
+	// need to replay every block from genesis.
+	SaveBalances(balances map[account.Account]uint) error
+
+	// LoadBalances returns the last snapshot saved by SaveBalances, and
+	// false if none has been saved yet.
+	LoadBalances() (map[account.Account]uint, bool, error)
+
+	// Close releases any resources held by the storage backend.
+	Close() error
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/account"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/state"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/transaction"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/wallet"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Run dispatches os.Args[1:] to a wallet/send subcommand.
+func Run() error {
+	if len(os.Args) < 2 {
+		printUsage()
+		return fmt.Errorf("no command given")
+	}
+
+	switch os.Args[1] {
+	case "createwallet":
+		return createWallet()
+	case "send":
+		return send(os.Args[2:])
+	default:
+		printUsage()
+		return fmt.Errorf("unknown command %q", os.Args[1])
+	}
+}
+
+func printUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  createwallet                          create a new wallet and print its address")
+	fmt.Println("  send -from ADDR -to ADDR -value N      sign and submit a transaction")
+}
+
+func walletsFilePath() (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cwd, "database", "wallets.dat"), nil
+}
+
+func createWallet() error {
+	path, err := walletsFilePath()
+	if err != nil {
+		return err
+	}
+
+	ws, err := wallet.LoadWallets(path)
+	if err != nil {
+		return err
+	}
+
+	addr, err := ws.CreateWallet()
+	if err != nil {
+		return err
+	}
+
+	if err := ws.SaveToFile(path); err != nil {
+		return err
+	}
+
+	fmt.Printf("New address: %s\n", addr)
+	return nil
+}
+
+func send(args []string) error {
+	fs := flag.NewFlagSet("send", flag.ExitOnError)
+	from := fs.String("from", "", "sender address")
+	to := fs.String("to", "", "recipient address")
+	value := fs.Uint("value", 0, "amount to send")
+	data := fs.String("data", "", "arbitrary transaction data")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *from == "" || *to == "" {
+		return fmt.Errorf("-from and -to are required")
+	}
+
+	path, err := walletsFilePath()
+	if err != nil {
+		return err
+	}
+
+	ws, err := wallet.LoadWallets(path)
+	if err != nil {
+		return err
+	}
+
+	w, ok := ws.GetWallet(account.NewAccount(*from))
+	if !ok {
+		return fmt.Errorf("no wallet known for address %s", *from)
+	}
+
+	tx := transaction.Tx{
+		From:  account.NewAccount(*from),
+		To:    account.NewAccount(*to),
+		Value: *value,
+		Data:  *data,
+	}
+	if err := tx.Sign(&w.PrivateKey); err != nil {
+		return err
+	}
+
+	s, err := state.NewStateFromDisk()
+	if err != nil {
+		return err
+	}
+
+	if err := s.Add(tx); err != nil {
+		return err
+	}
+	if err := s.Persist(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Sent %d from %s to %s\n", *value, *from, *to)
+	return nil
+}
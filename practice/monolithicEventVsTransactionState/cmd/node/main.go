@@ -0,0 +1,68 @@
+package main
+
+import (
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/account"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/miner"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/p2p"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/state"
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// node ties state, miner and p2p together into a single long-running
+// process that mines blocks and gossips with other nodes.
+func main() {
+	listenAddr := flag.String("listen", ":3000", "address to listen on for peer requests")
+	nodeName := flag.String("name", "node", "name this node advertises to peers")
+	minerAddr := flag.String("miner", "", "address credited with mined block rewards (required)")
+	bootstrap := flag.String("peers", "", "comma-separated bootstrap peer base URLs, e.g. http://localhost:3001")
+	blockTime := flag.Duration("block-time", 10*time.Second, "how often to mine a block")
+	flag.Parse()
+
+	if *minerAddr == "" {
+		log.Fatal("node: -miner is required")
+	}
+
+	s, err := state.NewStateFromDisk()
+	if err != nil {
+		log.Fatalf("node: could not load state: %v", err)
+	}
+
+	var peers []string
+	if *bootstrap != "" {
+		peers = strings.Split(*bootstrap, ",")
+	}
+
+	minerAccount := account.NewAccount(*minerAddr)
+
+	node := p2p.NewNode(p2p.NodeConfig{
+		NodeName:       *nodeName,
+		ListenAddr:     *listenAddr,
+		BootstrapPeers: peers,
+		MinerAddress:   minerAccount,
+	}, s)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := node.Start(ctx); err != nil {
+		log.Fatalf("node: could not start p2p: %v", err)
+	}
+	defer node.Stop()
+
+	m := miner.NewMiner(s, minerAccount, *blockTime, nil)
+	m.Start(ctx)
+	defer m.Stop()
+
+	log.Printf("node: %s listening on %s, mining to %s every %s", *nodeName, *listenAddr, minerAccount, *blockTime)
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	<-sigCh
+}
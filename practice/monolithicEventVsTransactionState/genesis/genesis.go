@@ -1,17 +1,68 @@
 package genesis
 
 import (
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/account"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/block"
 	"encoding/json"
+	"fmt"
 	"os"
+	"strings"
 )
 
+// ChainConfig holds chain-wide parameters that govern consensus, as
+// opposed to the one-off account balances in GenesisAlloc.
+type ChainConfig struct {
+	// BlockRewardValue is the coinbase reward the miner package credits
+	// to its address for each block it mines. Blocks mined by
+	// State.Persist carry no reward.
+	BlockRewardValue uint `json:"block_reward_value"`
+	// TargetBits is the proof-of-work difficulty new blocks must meet.
+	// LoadGenesis defaults it to block.DefaultTargetBits if unset.
+	TargetBits int `json:"target_bits"`
+	// MaxBlockTxs caps how many transactions a miner may include in a
+	// single block. Zero means unlimited.
+	MaxBlockTxs int `json:"max_block_txs"`
+	// ActivationHeights maps a future rule-change name to the block
+	// height at which it takes effect.
+	ActivationHeights map[string]uint64 `json:"activation_heights,omitempty"`
+}
+
+// GenesisAccount is one entry of a GenesisAlloc: the account's starting
+// balance and (for accounts that will send transactions before
+// receiving any) its starting nonce.
+type GenesisAccount struct {
+	Balance uint `json:"balance"`
+	Nonce   uint `json:"nonce,omitempty"`
+}
+
+// GenesisAlloc is the set of accounts funded at genesis.
+type GenesisAlloc map[account.Account]GenesisAccount
+
 type Genesis struct {
-	GenesisTime string          `json:"genesis_time"`
-	ChainID     string          `json:"chain_id"`
-	Balances    map[string]uint `json:"balances"`
+	GenesisTime string       `json:"genesis_time"`
+	ChainID     string       `json:"chain_id"`
+	Config      ChainConfig  `json:"config"`
+	Alloc       GenesisAlloc `json:"alloc"`
 }
 
-// Load the genesis file and return a Genesis struct
+// rawGenesisAccount mirrors GenesisAccount but decodes Balance as a
+// signed integer so LoadGenesis can reject negative values before they
+// silently wrap around as an unsigned int.
+type rawGenesisAccount struct {
+	Balance int64 `json:"balance"`
+	Nonce   uint  `json:"nonce,omitempty"`
+}
+
+type rawGenesis struct {
+	GenesisTime string                       `json:"genesis_time"`
+	ChainID     string                       `json:"chain_id"`
+	Config      ChainConfig                  `json:"config"`
+	Alloc       map[string]rawGenesisAccount `json:"alloc"`
+}
+
+// LoadGenesis reads and validates the genesis file at filename: the
+// chain ID must be set, no account may repeat (case-insensitively) and
+// no balance may be negative.
 func LoadGenesis(filename string) (*Genesis, error) {
 	file, err := os.Open(filename)
 	if err != nil {
@@ -19,10 +70,43 @@ func LoadGenesis(filename string) (*Genesis, error) {
 	}
 	defer file.Close()
 
-	var genesis Genesis
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&genesis); err != nil {
+	var raw rawGenesis
+	if err := json.NewDecoder(file).Decode(&raw); err != nil {
 		return nil, err
 	}
-	return &genesis, nil
+
+	if raw.ChainID == "" {
+		return nil, fmt.Errorf("genesis: chain_id must not be empty")
+	}
+
+	if raw.Config.TargetBits <= 0 {
+		raw.Config.TargetBits = block.DefaultTargetBits
+	}
+	if raw.Config.MaxBlockTxs < 0 {
+		return nil, fmt.Errorf("genesis: max_block_txs must not be negative")
+	}
+
+	gen := &Genesis{
+		GenesisTime: raw.GenesisTime,
+		ChainID:     raw.ChainID,
+		Config:      raw.Config,
+		Alloc:       make(GenesisAlloc, len(raw.Alloc)),
+	}
+
+	seen := make(map[string]string, len(raw.Alloc))
+	for addr, ga := range raw.Alloc {
+		lower := strings.ToLower(addr)
+		if original, ok := seen[lower]; ok {
+			return nil, fmt.Errorf("genesis: account %q duplicates %q (case-insensitive)", addr, original)
+		}
+		seen[lower] = addr
+
+		if ga.Balance < 0 {
+			return nil, fmt.Errorf("genesis: account %q has a negative balance", addr)
+		}
+
+		gen.Alloc[account.NewAccount(addr)] = GenesisAccount{Balance: uint(ga.Balance), Nonce: ga.Nonce}
+	}
+
+	return gen, nil
 }
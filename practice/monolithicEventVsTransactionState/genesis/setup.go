@@ -0,0 +1,110 @@
+package genesis
+
+import (
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/account"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/block"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/chain"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/transaction"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SetupGenesisBlock makes sure store was booted from gen: if store is
+// empty it mines and writes gen's genesis block, otherwise it checks
+// that the chain already on disk allocates the same accounts gen does,
+// returning a "genesis mismatch" error if not.
+func SetupGenesisBlock(store chain.Storage, gen *Genesis) (*block.Block, error) {
+	tip, err := store.GetTip()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tip) == 0 {
+		// The timestamp comes from gen.GenesisTime (RFC 3339, 0 if it does
+		// not parse) rather than the clock, so every node booted from the
+		// same genesis file mines the same genesis block.
+		var ts int64
+		if t, err := time.Parse(time.RFC3339, gen.GenesisTime); err == nil {
+			ts = t.Unix()
+		}
+		genesisBlock := &block.Block{Timestamp: ts, Transactions: allocTxs(gen), PrevBlockHash: []byte{}}
+		genesisBlock.Nonce, genesisBlock.Hash = block.NewProofOfWork(genesisBlock, gen.Config.TargetBits).Run()
+		if err := store.PutBlock(genesisBlock); err != nil {
+			return nil, err
+		}
+		return genesisBlock, nil
+	}
+
+	existing, err := firstBlock(store, tip)
+	if err != nil {
+		return nil, err
+	}
+
+	if !sameAlloc(existing.Transactions, allocTxs(gen)) {
+		return nil, fmt.Errorf("genesis mismatch: on-disk chain was not booted from this genesis file")
+	}
+
+	return existing, nil
+}
+
+// allocTxs turns gen's alloc into the reward transactions a genesis
+// block replays to establish starting balances. Accounts are visited in
+// sorted order so the result — and therefore the genesis block's Merkle
+// root and Hash — is deterministic: ranging over the GenesisAlloc map
+// directly would let two nodes booted from the byte-identical genesis
+// file mine different genesis hashes and never agree on a tip.
+func allocTxs(gen *Genesis) []transaction.Tx {
+	accts := make([]account.Account, 0, len(gen.Alloc))
+	for acct := range gen.Alloc {
+		accts = append(accts, acct)
+	}
+	sort.Slice(accts, func(i, j int) bool { return accts[i] < accts[j] })
+
+	txs := make([]transaction.Tx, 0, len(accts))
+	for _, acct := range accts {
+		txs = append(txs, transaction.Tx{To: acct, Value: gen.Alloc[acct].Balance, Data: "reward"})
+	}
+	return txs
+}
+
+// firstBlock walks storage back from tip to the block with no parent.
+func firstBlock(store chain.Storage, tip []byte) (*block.Block, error) {
+	hash := tip
+	for {
+		b, err := store.GetBlock(hash)
+		if err != nil {
+			return nil, err
+		}
+		if len(b.PrevBlockHash) == 0 {
+			return b, nil
+		}
+		hash = b.PrevBlockHash
+	}
+}
+
+// sameAlloc compares two genesis transaction sets irrespective of order
+// (maps have no stable iteration order).
+func sameAlloc(a, b []transaction.Tx) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	counts := make(map[string]int, len(a))
+	for _, tx := range a {
+		counts[allocKey(tx)]++
+	}
+	for _, tx := range b {
+		counts[allocKey(tx)]--
+	}
+	for _, n := range counts {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func allocKey(tx transaction.Tx) string {
+	return fmt.Sprintf("%s|%d|%s", tx.To, tx.Value, tx.Data)
+}
@@ -0,0 +1,140 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+)
+
+// MerkleNode is one node of a MerkleTree: a leaf hashes raw data, an
+// internal node hashes the concatenation of its two children's hashes.
+type MerkleNode struct {
+	Left  *MerkleNode
+	Right *MerkleNode
+	Hash  []byte
+}
+
+func newLeaf(data []byte) *MerkleNode {
+	hash := sha256.Sum256(data)
+	return &MerkleNode{Hash: hash[:]}
+}
+
+func newBranch(left, right *MerkleNode) *MerkleNode {
+	hash := sha256.Sum256(append(append([]byte{}, left.Hash...), right.Hash...))
+	return &MerkleNode{Left: left, Right: right, Hash: hash[:]}
+}
+
+// MerkleTree is a binary hash tree over a set of data blocks (e.g.
+// serialized transactions), used to commit to them with a single root
+// hash and to prove membership without revealing every leaf.
+type MerkleTree struct {
+	Root *MerkleNode
+
+	// leafHashes holds sha256(data) for every leaf, in the order used to
+	// build Root, one entry per data item; the duplicate added for an odd
+	// level is not stored here (Proof re-adds it as it walks up).
+	// It lets Proof locate a leaf without re-walking the tree.
+	leafHashes [][]byte
+}
+
+// NewMerkleTree builds a tree over data. Odd levels duplicate their last
+// node so every level can be paired off, matching Bitcoin-style trees.
+func NewMerkleTree(data [][]byte) *MerkleTree {
+	if len(data) == 0 {
+		return &MerkleTree{}
+	}
+
+	level := make([]*MerkleNode, len(data))
+	leafHashes := make([][]byte, len(data))
+	for i, d := range data {
+		level[i] = newLeaf(d)
+		leafHashes[i] = level[i].Hash
+	}
+
+	for len(level) > 1 {
+		if len(level)%2 != 0 {
+			level = append(level, level[len(level)-1])
+		}
+
+		var next []*MerkleNode
+		for i := 0; i < len(level); i += 2 {
+			next = append(next, newBranch(level[i], level[i+1]))
+		}
+		level = next
+	}
+
+	return &MerkleTree{Root: level[0], leafHashes: leafHashes}
+}
+
+// RootHash returns the tree's root hash, or nil for an empty tree.
+func (t *MerkleTree) RootHash() []byte {
+	if t.Root == nil {
+		return nil
+	}
+	return t.Root.Hash
+}
+
+// Proof returns the sibling hashes and left/right flags needed to verify
+// that txID (the raw, un-hashed leaf data) is included in the tree. A
+// true flag means the sibling at that step is the right-hand node.
+func (t *MerkleTree) Proof(txID []byte) ([][]byte, []bool, error) {
+	leafHash := sha256.Sum256(txID)
+
+	index := -1
+	for i, h := range t.leafHashes {
+		if bytes.Equal(h, leafHash[:]) {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return nil, nil, fmt.Errorf("transaction not found in merkle tree")
+	}
+
+	level := t.leafHashes
+	var proof [][]byte
+	var sides []bool
+
+	for len(level) > 1 {
+		if len(level)%2 != 0 {
+			level = append(level, level[len(level)-1])
+		}
+
+		siblingIndex := index ^ 1
+		proof = append(proof, level[siblingIndex])
+		sides = append(sides, index%2 == 0) // even index => current is left, sibling is right
+
+		var next [][]byte
+		for i := 0; i < len(level); i += 2 {
+			hash := sha256.Sum256(append(append([]byte{}, level[i]...), level[i+1]...))
+			next = append(next, hash[:])
+		}
+		level = next
+		index /= 2
+	}
+
+	return proof, sides, nil
+}
+
+// VerifyMerkleProof reports whether txID is included under root, given a
+// proof produced by MerkleTree.Proof.
+func VerifyMerkleProof(txID, root []byte, proof [][]byte, sides []bool) bool {
+	if len(proof) != len(sides) {
+		return false
+	}
+
+	hash := sha256.Sum256(txID)
+	current := hash[:]
+
+	for i, sibling := range proof {
+		var combined [32]byte
+		if sides[i] {
+			combined = sha256.Sum256(append(append([]byte{}, current...), sibling...))
+		} else {
+			combined = sha256.Sum256(append(append([]byte{}, sibling...), current...))
+		}
+		current = combined[:]
+	}
+
+	return bytes.Equal(current, root)
+}
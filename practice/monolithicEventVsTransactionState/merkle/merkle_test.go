@@ -0,0 +1,63 @@
+package merkle
+
+import "testing"
+
+func TestRootHashStableForSameData(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+
+	root1 := NewMerkleTree(data).RootHash()
+	root2 := NewMerkleTree(data).RootHash()
+
+	if string(root1) != string(root2) {
+		t.Fatalf("expected equal roots for identical input, got %x and %x", root1, root2)
+	}
+}
+
+func TestRootHashChangesWithOddLeafCount(t *testing.T) {
+	even := NewMerkleTree([][]byte{[]byte("a"), []byte("b")}).RootHash()
+	odd := NewMerkleTree([][]byte{[]byte("a"), []byte("b"), []byte("c")}).RootHash()
+
+	if string(even) == string(odd) {
+		t.Fatalf("expected different roots for different leaf sets")
+	}
+}
+
+func TestProofVerifiesForEveryLeafWithOddCount(t *testing.T) {
+	leaves := [][]byte{[]byte("tx1"), []byte("tx2"), []byte("tx3"), []byte("tx4"), []byte("tx5")}
+	tree := NewMerkleTree(leaves)
+	root := tree.RootHash()
+
+	for _, leaf := range leaves {
+		proof, sides, err := tree.Proof(leaf)
+		if err != nil {
+			t.Fatalf("Proof(%s): %v", leaf, err)
+		}
+		if !VerifyMerkleProof(leaf, root, proof, sides) {
+			t.Errorf("VerifyMerkleProof(%s) = false, want true", leaf)
+		}
+	}
+}
+
+func TestProofRejectsUnknownLeaf(t *testing.T) {
+	leaves := [][]byte{[]byte("tx1"), []byte("tx2"), []byte("tx3")}
+	tree := NewMerkleTree(leaves)
+
+	if _, _, err := tree.Proof([]byte("not-a-member")); err == nil {
+		t.Fatal("expected error proving a leaf that is not in the tree")
+	}
+}
+
+func TestVerifyMerkleProofRejectsTamperedLeaf(t *testing.T) {
+	leaves := [][]byte{[]byte("tx1"), []byte("tx2"), []byte("tx3")}
+	tree := NewMerkleTree(leaves)
+	root := tree.RootHash()
+
+	proof, sides, err := tree.Proof([]byte("tx1"))
+	if err != nil {
+		t.Fatalf("Proof: %v", err)
+	}
+
+	if VerifyMerkleProof([]byte("tx1-tampered"), root, proof, sides) {
+		t.Fatal("expected proof to fail for a tampered leaf")
+	}
+}
@@ -0,0 +1,106 @@
+package miner
+
+import (
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/account"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/state"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/transaction"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// BalanceReader is the locked, read-only view of a State a Ticker gets.
+// It deliberately excludes State's raw Balances field, which is mutated
+// by Apply/Add/ExtendChain/MineBlock on other goroutines while a
+// Ticker runs.
+type BalanceReader interface {
+	Balance(acct account.Account) uint
+	Snapshot() map[account.Account]uint
+}
+
+// Ticker lets higher layers inject system transactions (e.g. inflation,
+// slashing) once per block, before it is mined — analogous to a
+// per-block tick in ABCI-style applications. It may be nil.
+type Ticker func(s BalanceReader) ([]transaction.Tx, error)
+
+// Miner periodically assembles the mempool into a block, crediting
+// Address with the chain's configured block reward.
+type Miner struct {
+	State    *state.State
+	Address  account.Account
+	Interval time.Duration
+	Tick     Ticker
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewMiner builds a Miner that mines blocks for s every interval,
+// rewarding address. tick may be nil.
+func NewMiner(s *state.State, address account.Account, interval time.Duration, tick Ticker) *Miner {
+	return &Miner{State: s, Address: address, Interval: interval, Tick: tick}
+}
+
+// Start mines blocks at the configured cadence until ctx is cancelled or
+// Stop is called.
+func (m *Miner) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	m.cancel = cancel
+	m.done = make(chan struct{})
+
+	go func() {
+		defer close(m.done)
+
+		ticker := time.NewTicker(m.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := m.mineOnce(); err != nil {
+					log.Printf("miner: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop halts mining and waits for the in-flight cycle to finish.
+func (m *Miner) Stop() {
+	if m.cancel == nil {
+		return
+	}
+	m.cancel()
+	<-m.done
+}
+
+// mineOnce runs the Tick hook, then hands the coinbase reward (plus any
+// Tick transactions) to state.MineBlock to assemble, apply and mine
+// alongside the pending mempool. Delegating to State keeps every
+// Balances/TxMempool/Chain mutation behind State's own lock, so mineOnce
+// is safe to run concurrently with HTTP handlers and the p2p sync loop
+// mutating the same State.
+func (m *Miner) mineOnce() error {
+	var sysTxs []transaction.Tx
+	if m.Tick != nil {
+		txs, err := m.Tick(m.State)
+		if err != nil {
+			return fmt.Errorf("miner: tick: %v", err)
+		}
+		sysTxs = txs
+	}
+
+	coinbase := transaction.Tx{To: m.Address, Value: m.State.Config.BlockRewardValue, Data: "reward"}
+
+	extra := make([]transaction.Tx, 0, len(sysTxs)+1)
+	extra = append(extra, coinbase)
+	extra = append(extra, sysTxs...)
+
+	if _, err := m.State.MineBlock(extra); err != nil {
+		return fmt.Errorf("miner: %v", err)
+	}
+	return nil
+}
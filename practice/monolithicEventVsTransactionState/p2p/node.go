@@ -0,0 +1,174 @@
+package p2p
+
+import (
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/account"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/state"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/transaction"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// NodeConfig configures a Node's network identity and behavior.
+type NodeConfig struct {
+	NodeName       string
+	ListenAddr     string
+	BootstrapPeers []string
+	MinerAddress   account.Account
+	PollInterval   time.Duration
+}
+
+// peerInfo is what a node advertises about itself at GET /info.
+type peerInfo struct {
+	NodeName string   `json:"node_name"`
+	TipHash  string   `json:"chain_tip_hash"`
+	Height   int      `json:"height"`
+	Peers    []string `json:"known_peers"`
+}
+
+// Node lets a State participate in a small gossip network: it serves its
+// own tip/peer list over HTTP, polls known peers for theirs, pulls in
+// any blocks they have that it doesn't, and relays submitted
+// transactions.
+type Node struct {
+	cfg   NodeConfig
+	state *state.State
+
+	mu    sync.Mutex
+	peers map[string]struct{}
+
+	server *http.Server
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewNode builds a Node serving and synchronizing s.
+func NewNode(cfg NodeConfig, s *state.State) *Node {
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = 10 * time.Second
+	}
+
+	peers := make(map[string]struct{}, len(cfg.BootstrapPeers))
+	for _, p := range cfg.BootstrapPeers {
+		peers[p] = struct{}{}
+	}
+
+	return &Node{cfg: cfg, state: s, peers: peers}
+}
+
+// Start serves the node's HTTP endpoint, performs an initial handshake
+// with its bootstrap peers, and begins polling them on a loop.
+func (n *Node) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/info", n.handleInfo)
+	mux.HandleFunc("/blocks/", n.handleGetBlock)
+	mux.HandleFunc("/tx", n.handleSubmitTx)
+
+	ln, err := net.Listen("tcp", n.cfg.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("p2p: could not listen on %s: %v", n.cfg.ListenAddr, err)
+	}
+
+	n.server = &http.Server{Handler: mux}
+	go func() {
+		if err := n.server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			log.Printf("p2p: server stopped: %v", err)
+		}
+	}()
+
+	runCtx, cancel := context.WithCancel(ctx)
+	n.cancel = cancel
+	n.done = make(chan struct{})
+
+	n.handshake()
+
+	go func() {
+		defer close(n.done)
+
+		ticker := time.NewTicker(n.cfg.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-runCtx.Done():
+				return
+			case <-ticker.C:
+				n.pollPeers()
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops polling peers and shuts down the HTTP server.
+func (n *Node) Stop() error {
+	if n.cancel != nil {
+		n.cancel()
+		<-n.done
+	}
+	if n.server != nil {
+		return n.server.Close()
+	}
+	return nil
+}
+
+// BroadcastTx gossips tx to every known peer so it can be mined even if
+// it was submitted on a different node.
+func (n *Node) BroadcastTx(tx transaction.Tx) {
+	data, err := json.Marshal(tx)
+	if err != nil {
+		log.Printf("p2p: could not encode transaction for broadcast: %v", err)
+		return
+	}
+
+	for _, peer := range n.knownPeers() {
+		go func(peer string) {
+			resp, err := http.Post(peer+"/tx", "application/json", bytes.NewReader(data))
+			if err != nil {
+				log.Printf("p2p: could not broadcast tx to %s: %v", peer, err)
+				return
+			}
+			resp.Body.Close()
+		}(peer)
+	}
+}
+
+func (n *Node) knownPeers() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	peers := make([]string, 0, len(n.peers))
+	for p := range n.peers {
+		peers = append(peers, p)
+	}
+	return peers
+}
+
+func (n *Node) addPeer(addr string) {
+	if addr == "" || addr == n.cfg.ListenAddr {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.peers[addr] = struct{}{}
+}
+
+func (n *Node) handshake() {
+	for _, peer := range n.knownPeers() {
+		info, err := n.fetchInfo(peer)
+		if err != nil {
+			log.Printf("p2p: handshake with %s failed: %v", peer, err)
+			continue
+		}
+		for _, p := range info.Peers {
+			n.addPeer(p)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package p2p
+
+import (
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/block"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/transaction"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+)
+
+func (n *Node) handleInfo(w http.ResponseWriter, r *http.Request) {
+	info := peerInfo{
+		NodeName: n.cfg.NodeName,
+		TipHash:  hex.EncodeToString(n.state.Tip()),
+		Height:   n.height(),
+		Peers:    n.knownPeers(),
+	}
+	json.NewEncoder(w).Encode(info)
+}
+
+func (n *Node) handleGetBlock(w http.ResponseWriter, r *http.Request) {
+	hash, err := hex.DecodeString(r.URL.Path[len("/blocks/"):])
+	if err != nil {
+		http.Error(w, "bad block hash", http.StatusBadRequest)
+		return
+	}
+
+	b, err := n.state.GetBlock(hash)
+	if err != nil {
+		http.Error(w, "block not found", http.StatusNotFound)
+		return
+	}
+
+	data, err := b.Serialize()
+	if err != nil {
+		http.Error(w, "could not serialize block", http.StatusInternalServerError)
+		return
+	}
+	w.Write(data)
+}
+
+func (n *Node) handleSubmitTx(w http.ResponseWriter, r *http.Request) {
+	var tx transaction.Tx
+	if err := json.NewDecoder(r.Body).Decode(&tx); err != nil {
+		http.Error(w, "bad transaction", http.StatusBadRequest)
+		return
+	}
+
+	if err := n.state.Add(tx); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Gossip it on so a tx submitted on one node reaches every other
+	// node's mempool, not just the one it was submitted to.
+	n.BroadcastTx(tx)
+}
+
+func (n *Node) fetchInfo(peer string) (*peerInfo, error) {
+	resp, err := http.Get(peer + "/info")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var info peerInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (n *Node) fetchBlock(peer, hashHex string) (*block.Block, error) {
+	resp, err := http.Get(peer + "/blocks/" + hashHex)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return block.Deserialize(data)
+}
+
+func (n *Node) height() int {
+	height, err := n.state.Height()
+	if err != nil {
+		return 0
+	}
+	return height
+}
+
+// chainWork returns the cumulative proof-of-work for a chain of the
+// given height, used to pick a winner between competing chains.
+func chainWork(height int) *big.Int {
+	return new(big.Int).Mul(big.NewInt(int64(height)), block.Work())
+}
+
+// pollPeers asks every known peer for its tip, merges in any peers it
+// knows about, and, visiting peers one at a time, syncs from each peer
+// whose cumulative work beats our own chain as it stands at that point.
+func (n *Node) pollPeers() {
+	myHeight := n.height()
+
+	for _, peer := range n.knownPeers() {
+		info, err := n.fetchInfo(peer)
+		if err != nil {
+			log.Printf("p2p: could not reach peer %s: %v", peer, err)
+			continue
+		}
+
+		for _, p := range info.Peers {
+			n.addPeer(p)
+		}
+
+		if chainWork(info.Height).Cmp(chainWork(myHeight)) <= 0 {
+			continue
+		}
+
+		if err := n.syncFrom(peer, info.TipHash); err != nil {
+			log.Printf("p2p: could not sync from %s: %v", peer, err)
+			continue
+		}
+		myHeight = n.height()
+	}
+}
+
+// syncFrom walks peer's chain back from tipHex, fetching any blocks we
+// don't already have, then applies them oldest-first. It only extends
+// our current tip; it does not handle reorgs off a common ancestor
+// further back than our own tip.
+func (n *Node) syncFrom(peer, tipHex string) error {
+	tipHash, err := hex.DecodeString(tipHex)
+	if err != nil {
+		return fmt.Errorf("bad tip hash %q: %v", tipHex, err)
+	}
+
+	var missing []*block.Block
+	hash := tipHash
+	for len(hash) > 0 {
+		if _, err := n.state.GetBlock(hash); err == nil {
+			break // we already have this block and everything under it
+		}
+
+		b, err := n.fetchBlock(peer, hex.EncodeToString(hash))
+		if err != nil {
+			return fmt.Errorf("could not fetch block %x: %v", hash, err)
+		}
+
+		missing = append(missing, b)
+		hash = b.PrevBlockHash
+	}
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		if err := n.state.ExtendChain(missing[i]); err != nil {
+			return fmt.Errorf("could not extend chain with block %x: %v", missing[i].Hash, err)
+		}
+	}
+
+	return nil
+}
@@ -1,19 +1,33 @@
 package state
 
 import (
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/account"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/block"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/chain"
 	"blockchain-in-go/practice/monolithicEventVsTransactionState/genesis"
 	"blockchain-in-go/practice/monolithicEventVsTransactionState/transaction"
-	"bufio"
-	"encoding/json"
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 )
 
+// State is shared by a node's HTTP handlers, its miner and its p2p sync
+// loop, all of which run on their own goroutines, so every exported
+// method that reads or mutates Balances/TxMempool/Chain takes mu.
+// Balances itself stays a plain map only so code inside this package can
+// manipulate it directly under mu; callers outside the package must use
+// Balance or Snapshot instead of reading the field, which is mutated by
+// Apply, Add, ExtendChain and MineBlock on whatever goroutine calls
+// them.
 type State struct {
-	Balances  map[string]uint
+	Balances  map[account.Account]uint
 	TxMempool []transaction.Tx
-	DbFile    *os.File
+	Chain     *chain.Chain
+	Config    genesis.ChainConfig
+
+	mu sync.Mutex
 }
 
 // Function to read the genesis file and create the initial state
@@ -31,36 +45,57 @@ func NewStateFromDisk() (*State, error) {
 		return nil, err
 	}
 
-	// Set up initial balances based on the genesis file
-	balances := make(map[string]uint)
-	for account, balance := range gen.Balances {
-		balances[account] = balance
+	// Open (or create) the block chain database. Chains created before
+	// block-level signature verification existed (the pre-block tx.db
+	// log) cannot be imported here: their transactions have no R/S/PubKey
+	// and would fail tx.Verify on replay, so there is no migration path
+	// for them.
+	chainDbFilePath := filepath.Join(cwd, "database", "chain.db")
+	storage, err := chain.OpenBoltStorage(chainDbFilePath)
+	if err != nil {
+		return nil, err
 	}
 
-	// Open transaction DB file
-	txDbFilePath := filepath.Join(cwd, "database", "tx.db")
-	f, err := os.OpenFile(txDbFilePath, os.O_APPEND|os.O_RDWR, 0600)
+	c, err := chain.LoadChain(storage)
 	if err != nil {
 		return nil, err
 	}
 
-	// Initialize state
 	state := &State{
-		Balances:  balances,
+		Balances:  make(map[account.Account]uint),
 		TxMempool: make([]transaction.Tx, 0),
-		DbFile:    f,
+		Chain:     c,
+		Config:    gen.Config,
+	}
+
+	// Write gen's genesis block on a fresh chain, or confirm the chain
+	// already on disk was booted from this same genesis.
+	if _, err := genesis.SetupGenesisBlock(storage, gen); err != nil {
+		return nil, err
+	}
+	c.Tip, err = storage.GetTip()
+	if err != nil {
+		return nil, err
 	}
 
-	// Rebuild the state by reading transactions from the transaction DB
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		var tx transaction.Tx
-		if err := json.Unmarshal(scanner.Bytes(), &tx); err != nil {
+	// Use the last saved balance snapshot if there is one, so a restart
+	// doesn't have to replay the whole chain. Otherwise replay every
+	// block in order and snapshot the result for next time.
+	if balances, ok, err := c.LoadBalances(); err != nil {
+		return nil, err
+	} else if ok {
+		state.Balances = balances
+	} else {
+		blocks, err := c.BlocksInOrder()
+		if err != nil {
 			return nil, err
 		}
-
-		// Apply the transaction to the state
-		if err := state.Apply(tx); err != nil {
+		for _, b := range blocks {
+			if err := state.applyBlock(b); err != nil {
+				return nil, err
+			}
+		}
+		if err := c.SaveBalances(state.Balances); err != nil {
 			return nil, err
 		}
 	}
@@ -68,13 +103,77 @@ func NewStateFromDisk() (*State, error) {
 	return state, nil
 }
 
-// Apply a transaction to update the state
-func (s *State) Apply(tx transaction.Tx) error {
+// applyBlock validates a block's proof of work and applies its
+// transactions atomically: if any transaction is invalid, none of the
+// block's balance changes are kept. Callers must hold s.mu.
+func (s *State) applyBlock(b *block.Block) error {
+	if !block.NewProofOfWork(b, s.Config.TargetBits).Validate() {
+		return fmt.Errorf("block %x has an invalid proof of work", b.Hash)
+	}
+
+	snapshot := make(map[account.Account]uint, len(s.Balances))
+	for acct, balance := range s.Balances {
+		snapshot[acct] = balance
+	}
+
+	for _, tx := range b.Transactions {
+		if err := s.apply(tx); err != nil {
+			s.Balances = snapshot
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExtendChain validates a block mined elsewhere (e.g. by a peer) against
+// the current state and, if it applies cleanly on top of the tip,
+// appends it to the chain and refreshes the balance snapshot. Balance
+// changes are rolled back if the block is invalid or fails to persist.
+// b must build directly on the current tip: ExtendChain does not handle
+// reorgs onto a competing branch.
+func (s *State) ExtendChain(b *block.Block) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !bytes.Equal(b.PrevBlockHash, s.Chain.Tip) {
+		return fmt.Errorf("block %x does not extend the current tip %x", b.Hash, s.Chain.Tip)
+	}
+
+	snapshot := make(map[account.Account]uint, len(s.Balances))
+	for acct, balance := range s.Balances {
+		snapshot[acct] = balance
+	}
+
+	if err := s.applyBlock(b); err != nil {
+		s.Balances = snapshot
+		return err
+	}
+
+	if err := s.Chain.AddBlock(b, s.Config.TargetBits); err != nil {
+		s.Balances = snapshot
+		return err
+	}
+
+	if err := s.Chain.SaveBalances(s.Balances); err != nil {
+		s.Balances = snapshot
+		return err
+	}
+
+	return nil
+}
+
+// apply is Apply's unlocked core, used by callers that already hold s.mu.
+func (s *State) apply(tx transaction.Tx) error {
 	if tx.IsReward() {
 		s.Balances[tx.To] += tx.Value
 		return nil
 	}
 
+	if !tx.Verify() {
+		return fmt.Errorf("invalid signature on transaction from %s", tx.From)
+	}
+
 	if tx.Value > s.Balances[tx.From] {
 		return fmt.Errorf("insufficient balance for %s", tx.From)
 	}
@@ -85,32 +184,166 @@ func (s *State) Apply(tx transaction.Tx) error {
 	return nil
 }
 
+// Apply a transaction to update the state.
+func (s *State) Apply(tx transaction.Tx) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.apply(tx)
+}
+
 // Add a new transaction to the state
 func (s *State) Add(tx transaction.Tx) error {
-	if err := s.Apply(tx); err != nil {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.apply(tx); err != nil {
 		return err
 	}
 	s.TxMempool = append(s.TxMempool, tx)
 	return nil
 }
 
-// Persist the transactions to disk
+// Persist mines a new block from the current mempool and appends it to
+// the chain. If the mempool holds more than Config.MaxBlockTxs
+// transactions, only the first MaxBlockTxs are mined and the rest are
+// left in the mempool for the next call.
 func (s *State) Persist() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.TxMempool) == 0 {
+		return nil
+	}
+
 	mempool := make([]transaction.Tx, len(s.TxMempool))
 	copy(mempool, s.TxMempool)
 
-	for _, tx := range mempool {
-		txJson, err := json.Marshal(tx)
-		if err != nil {
-			return err
-		}
+	blockTxs := mempool
+	if max := s.Config.MaxBlockTxs; max > 0 && len(blockTxs) > max {
+		blockTxs = blockTxs[:max]
+	}
 
-		if _, err := s.DbFile.Write(append(txJson, '\n')); err != nil {
-			return err
-		}
+	newBlock := block.NewBlock(blockTxs, s.Chain.Tip, s.Config.TargetBits)
+	if err := s.Chain.AddBlock(newBlock, s.Config.TargetBits); err != nil {
+		return err
+	}
 
-		// Remove the transaction from the mempool after persisting
-		s.TxMempool = s.TxMempool[1:]
+	if err := s.Chain.SaveBalances(s.Balances); err != nil {
+		return err
 	}
+
+	s.TxMempool = mempool[len(blockTxs):]
 	return nil
 }
+
+// MineBlock assembles a block from extra (e.g. a coinbase and any Tick
+// transactions, applied here) followed by the pending mempool (already
+// applied to Balances when it was added via Add, so not reapplied),
+// mines it at Config.TargetBits and appends it to the chain. It runs
+// under s.mu, so it is safe to call concurrently with Add, ExtendChain
+// and Persist. If Config.MaxBlockTxs caps the block below len(extra)+
+// len(TxMempool), the mempool overflow stays queued for the next call.
+// extra itself is never truncated: every element of extra is applied
+// and none are left out of the mined block, so Balances can never
+// credit a transaction replaying the chain would never see. If extra
+// alone is larger than MaxBlockTxs, MineBlock refuses to mine.
+func (s *State) MineBlock(extra []transaction.Tx) (*block.Block, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	max := s.Config.MaxBlockTxs
+	if max > 0 && len(extra) > max {
+		return nil, fmt.Errorf("could not mine: %d coinbase/tick transactions exceed MaxBlockTxs %d", len(extra), max)
+	}
+
+	blockTxs := make([]transaction.Tx, 0, len(extra)+len(s.TxMempool))
+	blockTxs = append(blockTxs, extra...)
+	blockTxs = append(blockTxs, s.TxMempool...)
+
+	if max > 0 && len(blockTxs) > max {
+		blockTxs = blockTxs[:max]
+	}
+	includedMempool := len(blockTxs) - len(extra)
+
+	snapshot := make(map[account.Account]uint, len(s.Balances))
+	for acct, balance := range s.Balances {
+		snapshot[acct] = balance
+	}
+
+	for _, tx := range extra {
+		if err := s.apply(tx); err != nil {
+			s.Balances = snapshot
+			return nil, fmt.Errorf("could not apply transaction: %v", err)
+		}
+	}
+
+	newBlock := block.NewBlock(blockTxs, s.Chain.Tip, s.Config.TargetBits)
+	if err := s.Chain.AddBlock(newBlock, s.Config.TargetBits); err != nil {
+		s.Balances = snapshot
+		return nil, fmt.Errorf("could not persist mined block: %v", err)
+	}
+
+	if err := s.Chain.SaveBalances(s.Balances); err != nil {
+		s.Balances = snapshot
+		return nil, fmt.Errorf("could not snapshot balances: %v", err)
+	}
+
+	s.TxMempool = s.TxMempool[includedMempool:]
+	return newBlock, nil
+}
+
+// Balance returns acct's current balance, 0 if it has none. Callers
+// outside this package (e.g. a miner.Ticker) must use this or Snapshot
+// instead of reading Balances directly, since it is mutated under s.mu
+// by Apply, Add, ExtendChain and MineBlock on whatever goroutine
+// calls them.
+func (s *State) Balance(acct account.Account) uint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Balances[acct]
+}
+
+// Snapshot returns a copy of every account's balance, safe to range
+// over without holding s.mu.
+func (s *State) Snapshot() map[account.Account]uint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snap := make(map[account.Account]uint, len(s.Balances))
+	for acct, balance := range s.Balances {
+		snap[acct] = balance
+	}
+	return snap
+}
+
+// Tip returns the chain's current tip hash. Callers that only need to
+// read chain state (e.g. p2p) should use this, GetBlock and Height
+// rather than reaching into s.Chain directly, since Chain.Tip is
+// mutated under s.mu by ExtendChain, Persist and MineBlock.
+func (s *State) Tip() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tip := make([]byte, len(s.Chain.Tip))
+	copy(tip, s.Chain.Tip)
+	return tip
+}
+
+// GetBlock looks up a block by its hash.
+func (s *State) GetBlock(hash []byte) (*block.Block, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Chain.GetBlock(hash)
+}
+
+// Height returns the number of blocks in the chain.
+func (s *State) Height() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	blocks, err := s.Chain.BlocksInOrder()
+	if err != nil {
+		return 0, err
+	}
+	return len(blocks), nil
+}
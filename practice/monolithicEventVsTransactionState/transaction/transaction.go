@@ -1,13 +1,86 @@
 package transaction
 
+import (
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/account"
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/wallet"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
 type Tx struct {
-	From  string `json:"from"`
-	To    string `json:"to"`
-	Value uint   `json:"value"`
-	Data  string `json:"data"`
+	From  account.Account `json:"from"`
+	To    account.Account `json:"to"`
+	Value uint            `json:"value"`
+	Data  string          `json:"data"`
+
+	// Signature, populated by Sign and checked by Verify. Reward
+	// transactions (minted by the protocol, not a wallet) leave these nil.
+	R      []byte `json:"r,omitempty"`
+	S      []byte `json:"s,omitempty"`
+	PubKey []byte `json:"pub_key,omitempty"`
 }
 
 // Check if the transaction is a reward
 func (t Tx) IsReward() bool {
 	return t.Data == "reward"
 }
+
+// hash returns the digest Sign and Verify operate over: every field that
+// is fixed before signing, i.e. everything except R/S/PubKey.
+func (t Tx) hash() [32]byte {
+	unsigned := Tx{From: t.From, To: t.To, Value: t.Value, Data: t.Data}
+	data, err := json.Marshal(unsigned)
+	if err != nil {
+		panic(err)
+	}
+	return sha256.Sum256(data)
+}
+
+// Sign signs the transaction with priv, storing the resulting signature
+// and public key on the transaction itself.
+func (t *Tx) Sign(priv *ecdsa.PrivateKey) error {
+	hash := t.hash()
+
+	r, s, err := ecdsa.Sign(rand.Reader, priv, hash[:])
+	if err != nil {
+		return fmt.Errorf("could not sign transaction: %v", err)
+	}
+
+	t.R = r.Bytes()
+	t.S = s.Bytes()
+	t.PubKey = elliptic.Marshal(priv.PublicKey.Curve, priv.PublicKey.X, priv.PublicKey.Y)
+	return nil
+}
+
+// Verify reports whether the transaction's signature was produced by the
+// private key matching From. It is not enough for R/S to be a valid
+// ECDSA signature under PubKey: PubKey itself must hash to From, or
+// anyone could sign with their own key while naming a victim as From.
+func (t Tx) Verify() bool {
+	if t.R == nil || t.S == nil || t.PubKey == nil {
+		return false
+	}
+
+	if wallet.AddressFromPubKey(t.PubKey) != t.From {
+		return false
+	}
+
+	curve := elliptic.P256()
+	x, y := elliptic.Unmarshal(curve, t.PubKey)
+	if x == nil {
+		return false
+	}
+
+	pub := ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	hash := t.hash()
+
+	r := new(big.Int).SetBytes(t.R)
+	s := new(big.Int).SetBytes(t.S)
+
+	return ecdsa.Verify(&pub, hash[:], r, s)
+}
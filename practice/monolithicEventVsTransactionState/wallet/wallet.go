@@ -0,0 +1,73 @@
+package wallet
+
+import (
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/account"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/mr-tron/base58"
+	"golang.org/x/crypto/ripemd160"
+)
+
+const (
+	// version is prepended to a public key hash before Base58Check
+	// encoding, mirroring Bitcoin-style address versioning.
+	version     = byte(0x00)
+	checksumLen = 4
+)
+
+// Wallet holds a single ECDSA keypair.
+type Wallet struct {
+	PrivateKey ecdsa.PrivateKey
+	PublicKey  []byte
+}
+
+// NewWallet generates a fresh P-256 keypair.
+func NewWallet() (*Wallet, error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate wallet key: %v", err)
+	}
+
+	pub := elliptic.Marshal(priv.PublicKey.Curve, priv.PublicKey.X, priv.PublicKey.Y)
+	return &Wallet{PrivateKey: *priv, PublicKey: pub}, nil
+}
+
+// Address derives the wallet's public, human-shareable address:
+// version ‖ RIPEMD160(SHA256(pubkey)), Base58Check-encoded.
+func (w *Wallet) Address() account.Account {
+	return AddressFromPubKey(w.PublicKey)
+}
+
+// AddressFromPubKey derives the address a raw public key maps to, using
+// the same version‖RIPEMD160(SHA256(pubkey)) Base58Check path Address
+// uses. It lets callers outside this package (e.g. verifying a
+// transaction's claimed sender) recompute the address a PubKey must
+// match without duplicating the encoding.
+func AddressFromPubKey(pubKey []byte) account.Account {
+	pubKeyHash := hashPubKey(pubKey)
+
+	versioned := append([]byte{version}, pubKeyHash...)
+	checksum := checksum(versioned)
+
+	full := append(versioned, checksum...)
+	return account.NewAccount(base58.Encode(full))
+}
+
+func hashPubKey(pubKey []byte) []byte {
+	sha := sha256.Sum256(pubKey)
+
+	hasher := ripemd160.New()
+	// ripemd160.Hash.Write never returns an error.
+	_, _ = hasher.Write(sha[:])
+	return hasher.Sum(nil)
+}
+
+func checksum(payload []byte) []byte {
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	return second[:checksumLen]
+}
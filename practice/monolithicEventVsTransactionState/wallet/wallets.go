@@ -0,0 +1,73 @@
+package wallet
+
+import (
+	"blockchain-in-go/practice/monolithicEventVsTransactionState/account"
+	"bytes"
+	"crypto/elliptic"
+	"encoding/gob"
+	"fmt"
+	"os"
+)
+
+func init() {
+	// ecdsa.PrivateKey embeds an elliptic.Curve interface; gob needs the
+	// concrete type registered to encode/decode it.
+	gob.Register(elliptic.P256())
+}
+
+// Wallets is a keyring of locally-held wallets, persisted to
+// database/wallets.dat.
+type Wallets struct {
+	Wallets map[account.Account]*Wallet
+}
+
+// LoadWallets reads the keyring from path, or returns an empty one if the
+// file does not exist yet.
+func LoadWallets(path string) (*Wallets, error) {
+	ws := &Wallets{Wallets: make(map[account.Account]*Wallet)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ws, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read wallets file: %v", err)
+	}
+
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(ws); err != nil {
+		return nil, fmt.Errorf("could not decode wallets file: %v", err)
+	}
+	return ws, nil
+}
+
+// CreateWallet generates a new wallet, adds it to the keyring and returns
+// its address.
+func (ws *Wallets) CreateWallet() (account.Account, error) {
+	w, err := NewWallet()
+	if err != nil {
+		return "", err
+	}
+
+	addr := w.Address()
+	ws.Wallets[addr] = w
+	return addr, nil
+}
+
+// GetWallet looks up a wallet by address.
+func (ws *Wallets) GetWallet(addr account.Account) (*Wallet, bool) {
+	w, ok := ws.Wallets[addr]
+	return w, ok
+}
+
+// SaveToFile persists the keyring to path.
+func (ws *Wallets) SaveToFile(path string) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(ws); err != nil {
+		return fmt.Errorf("could not encode wallets file: %v", err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("could not write wallets file: %v", err)
+	}
+	return nil
+}